@@ -0,0 +1,102 @@
+package glhl
+
+/*
+#cgo LDFLAGS: -framework OpenGL
+#include <stdlib.h>
+#include <dlfcn.h>
+#include <OpenGL/OpenGL.h>
+*/
+import "C"
+
+import "unsafe"
+
+// Context represents a headless OpenGL context, backed by CGL.
+type Context struct {
+	pix C.CGLPixelFormatObj
+	ctx C.CGLContextObj
+}
+
+// NewContext creates a new context with the specified version and flags.
+// The Debug flag has no effect: the context is created without a debug
+// attribute.
+func NewContext(major, minor int, flags Flag) (Context, error) {
+	return newContext(major, minor, flags, nil)
+}
+
+// NewSharedContext creates a new context that shares textures, buffers, and
+// other objects with parent, as if by passing share to CGLCreateContext.
+func NewSharedContext(parent Context, major, minor int, flags Flag) (Context, error) {
+	return newContext(major, minor, flags, parent.ctx)
+}
+
+func newContext(major, minor int, flags Flag, share C.CGLContextObj) (Context, error) {
+	if flags&ES != 0 {
+		return Context{}, ErrUnsupported
+	}
+
+	profile := C.CGLPixelFormatAttribute(C.kCGLOGLPVersion_3_2_Core)
+	switch {
+	case flags&Compatibility != 0:
+		profile = C.kCGLOGLPVersion_Legacy
+	case major >= 4:
+		profile = C.kCGLOGLPVersion_GL4_Core
+	}
+
+	attrs := []C.CGLPixelFormatAttribute{
+		C.kCGLPFAAccelerated,
+		C.kCGLPFAOpenGLProfile, profile,
+		0,
+	}
+
+	var pix C.CGLPixelFormatObj
+	var npix C.GLint
+	if code := C.CGLChoosePixelFormat(&attrs[0], &pix, &npix); code != 0 {
+		return Context{}, Error(code)
+	}
+	if pix == nil {
+		return Context{}, ErrNoConfig
+	}
+
+	var ctx C.CGLContextObj
+	if code := C.CGLCreateContext(pix, share, &ctx); code != 0 {
+		C.CGLDestroyPixelFormat(pix)
+		return Context{}, Error(code)
+	}
+
+	return Context{pix: pix, ctx: ctx}, nil
+}
+
+// Destroy cleans up the state surrounding a context
+func (ctx Context) Destroy() {
+	C.CGLDestroyContext(ctx.ctx)
+	C.CGLDestroyPixelFormat(ctx.pix)
+}
+
+// MakeContextCurrent activates the context, making it the new current OpenGL context.
+// gl.InitWithProcAddrFunc should be called with GetProcAddr after calling this function.
+func (ctx Context) MakeContextCurrent() {
+	if code := C.CGLSetCurrentContext(ctx.ctx); code != 0 {
+		panic(Error(code))
+	}
+}
+
+// Release deactivates the current context, making it available for use in other threads.
+func Release() {
+	if code := C.CGLSetCurrentContext(nil); code != 0 {
+		panic(Error(code))
+	}
+}
+
+// GetProcAddr gets the address of an OpenGL function. For use with gl.InitWithProcAddrFunc
+func GetProcAddr(name string) unsafe.Pointer {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	return unsafe.Pointer(C.dlsym(C.RTLD_DEFAULT, cname))
+}
+
+// Error represents a context initialization error
+type Error int32
+
+func (err Error) Error() string {
+	return C.GoString(C.CGLErrorString(C.CGLError(err)))
+}
@@ -0,0 +1,287 @@
+package glhl
+
+/*
+#cgo LDFLAGS: -lEGL -lgbm
+#include <stdlib.h>
+#include <string.h>
+#include <EGL/egl.h>
+#include <gbm.h>
+
+int glhlMakeContextCurrent(EGLDisplay dpy, EGLContext ctx, EGLenum api) {
+	if (!eglBindAPI(api)) goto error;
+	if (!eglMakeCurrent(dpy, EGL_NO_SURFACE, EGL_NO_SURFACE, ctx)) goto error;
+	return EGL_SUCCESS;
+error:
+	return eglGetError();
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unsafe"
+)
+
+// Context represents a headless OpenGL context, backed by EGL.
+type Context struct {
+	dpy   C.EGLDisplay
+	ctx   C.EGLContext
+	api   C.EGLenum // client API ctx was created against; see finishContext
+	debug bool
+	gbm   *C.struct_gbm_device
+	gbmf  *os.File
+}
+
+// NewContext creates a new context with the specified version and flags.
+//
+// If a default EGL display is not available, the DRM devices returned by
+// Devices are probed in order, skipping any that fail to produce a working
+// GBM/EGL display, until one succeeds. Use NewContextOnDevice to target a
+// specific device instead.
+func NewContext(major, minor int, flags Flag) (Context, error) {
+	var ctx Context
+	if err := initGeneric(&ctx); err != nil {
+		devs, devErr := Devices()
+		if devErr != nil || len(devs) == 0 {
+			return Context{}, err
+		}
+
+		found := false
+		for _, dev := range devs {
+			if initGBM(&ctx, dev) == nil {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return Context{}, err
+		}
+	}
+	return finishContext(ctx, major, minor, flags, C.EGLContext(C.EGL_NO_CONTEXT))
+}
+
+// NewContextOnDevice creates a new context on the given DRM device, as
+// returned by Devices. Use this on multi-GPU systems where the default
+// device probed by NewContext isn't the one you want.
+func NewContextOnDevice(dev Device, major, minor int, flags Flag) (Context, error) {
+	var ctx Context
+	if err := initGBM(&ctx, dev); err != nil {
+		return Context{}, err
+	}
+	return finishContext(ctx, major, minor, flags, C.EGLContext(C.EGL_NO_CONTEXT))
+}
+
+// NewSharedContext creates a new context that shares textures, buffers, and
+// other objects with parent, as if by passing share_context to
+// eglCreateContext. The two contexts must each be current on at most one
+// thread at a time, so shared contexts are typically used to render from a
+// second goroutine locked to its own OS thread.
+//
+// The returned Context does not inherit parent's GBM device, even if parent
+// is GBM-backed: NewGBMSurface and NewGBMBuffer will return ErrUnsupported
+// on it. Use NewContextOnDevice if the shared context also needs to create
+// GBM surfaces or buffers.
+func NewSharedContext(parent Context, major, minor int, flags Flag) (Context, error) {
+	ctx := Context{dpy: parent.dpy}
+	return finishContext(ctx, major, minor, flags, parent.ctx)
+}
+
+const egl_PLATFORM_GBM_MESA C.EGLenum = 0x31D7
+
+func initGeneric(ctx *Context) error {
+	ctx.dpy = C.eglGetDisplay(C.EGL_DEFAULT_DISPLAY)
+	if ctx.dpy == C.EGLDisplay(C.EGL_NO_DISPLAY) {
+		return ErrNoDisplay
+	}
+	if C.eglInitialize(ctx.dpy, nil, nil) == 0 {
+		return fmt.Errorf("eglInitialize: %w", eglError())
+	}
+	return nil
+}
+
+func initGBM(ctx *Context, dev Device) error {
+	ext := C.eglQueryString(C.EGLDisplay(C.EGL_NO_DISPLAY), C.EGL_EXTENSIONS)
+	if ext == nil || !strings.Contains(C.GoString(ext), "EGL_MESA_platform_gbm") {
+		return ErrUnsupported
+	}
+
+	var err error
+	ctx.gbmf, err = os.OpenFile(dev.Path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	ctx.gbm = C.gbm_create_device(C.int(ctx.gbmf.Fd()))
+	if ctx.gbm == nil {
+		ctx.gbmf.Close()
+		return ErrGBM
+	}
+
+	ctx.dpy = C.eglGetPlatformDisplay(egl_PLATFORM_GBM_MESA, unsafe.Pointer(ctx.gbm), nil)
+	if ctx.dpy == C.EGLDisplay(C.EGL_NO_DISPLAY) {
+		C.gbm_device_destroy(ctx.gbm)
+		ctx.gbmf.Close()
+		return ErrNoDisplay
+	}
+
+	if C.eglInitialize(ctx.dpy, nil, nil) == 0 {
+		C.gbm_device_destroy(ctx.gbm)
+		ctx.gbmf.Close()
+		return fmt.Errorf("eglInitialize: %w", eglError())
+	}
+
+	return nil
+}
+
+// finishContext chooses a config and creates the EGLContext, given a
+// Context whose display has already been initialized by initGeneric or
+// initGBM, sharing state with share (EGL_NO_CONTEXT for an unshared
+// context).
+func finishContext(ctx Context, major, minor int, flags Flag, share C.EGLContext) (Context, error) {
+	renderable := C.EGLint(C.EGL_OPENGL_BIT)
+	api := C.EGLenum(C.EGL_OPENGL_API)
+	if flags&ES != 0 {
+		api = C.EGL_OPENGL_ES_API
+		switch {
+		case major >= 3:
+			renderable = C.EGL_OPENGL_ES3_BIT
+		default:
+			renderable = C.EGL_OPENGL_ES2_BIT
+		}
+	}
+
+	configAttr := []C.EGLint{
+		C.EGL_CONFIG_CAVEAT, C.EGL_NONE, // Require hardware acceleration
+		C.EGL_CONFORMANT, renderable, // Require conformance with the requested API
+		C.EGL_RENDERABLE_TYPE, renderable, // Require support for the requested API
+		C.EGL_NONE,
+	}
+
+	var nconf C.EGLint
+	var conf C.EGLConfig
+	if C.eglChooseConfig(ctx.dpy, &configAttr[0], &conf, 1, &nconf) == 0 {
+		return Context{}, fmt.Errorf("eglChooseConfig: %w", eglError())
+	}
+	if nconf < 1 {
+		return Context{}, ErrNoConfig
+	}
+
+	if C.eglBindAPI(api) == 0 {
+		return Context{}, fmt.Errorf("eglBindAPI: %w", eglError())
+	}
+	ctx.api = api
+	ctx.debug = flags&Debug != 0
+
+	ctxAttr := []C.EGLint{
+		C.EGL_CONTEXT_MAJOR_VERSION, C.EGLint(major),
+		C.EGL_CONTEXT_MINOR_VERSION, C.EGLint(minor),
+	}
+	if flags&ES == 0 {
+		var profile C.EGLint
+		if flags&Compatibility != 0 {
+			profile |= C.EGL_CONTEXT_OPENGL_COMPATIBILITY_PROFILE_BIT
+		}
+		if flags&Core != 0 || profile == 0 {
+			profile |= C.EGL_CONTEXT_OPENGL_CORE_PROFILE_BIT
+		}
+		ctxAttr = append(ctxAttr, C.EGL_CONTEXT_OPENGL_PROFILE_MASK, profile)
+	}
+	if flags&Debug != 0 {
+		ctxAttr = append(ctxAttr, C.EGL_CONTEXT_OPENGL_DEBUG, 1)
+	}
+	ctxAttr = append(ctxAttr, C.EGL_NONE)
+
+	ctx.ctx = C.eglCreateContext(ctx.dpy, conf, share, &ctxAttr[0])
+	if err := eglError(); err != nil {
+		return Context{}, fmt.Errorf("eglCreateContext: %w", err)
+	}
+	return ctx, nil
+}
+
+// Destroy cleans up the state surrounding a context
+func (ctx Context) Destroy() {
+	if C.eglDestroyContext(ctx.dpy, ctx.ctx) == 0 {
+		panic(Error(C.eglGetError()))
+	}
+	if ctx.gbm != nil {
+		C.gbm_device_destroy(ctx.gbm)
+		ctx.gbmf.Close()
+	}
+}
+
+// MakeContextCurrent activates the context, making it the new current OpenGL context.
+// gl.InitWithProcAddrFunc should be called with GetProcAddr after calling this function.
+func (ctx Context) MakeContextCurrent() {
+	code := C.glhlMakeContextCurrent(ctx.dpy, ctx.ctx, ctx.api)
+	if code != C.EGL_SUCCESS {
+		panic(Error(code))
+	}
+	if ctx.debug {
+		enableDefaultDebugHandler(ctx)
+	}
+}
+
+// Release deactivates the current context, making it available for use in other threads.
+func Release() {
+	if C.eglReleaseThread() == 0 {
+		panic(Error(C.eglGetError()))
+	}
+}
+
+// GetProcAddr gets the address of an OpenGL function. For use with gl.InitWithProcAddrFunc
+func GetProcAddr(name string) unsafe.Pointer {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	return unsafe.Pointer(C.eglGetProcAddress(cname))
+}
+
+func eglError() error {
+	code := C.eglGetError()
+	if code == C.EGL_SUCCESS {
+		return nil
+	} else {
+		return Error(code)
+	}
+}
+
+// Error represents context initialization error
+type Error int
+
+func (err Error) Error() string {
+	switch err {
+	case C.EGL_NOT_INITIALIZED:
+		return "not initialized"
+	case C.EGL_BAD_ACCESS:
+		return "bad access"
+	case C.EGL_BAD_ALLOC:
+		return "bad alloc"
+	case C.EGL_BAD_ATTRIBUTE:
+		return "bad attribute"
+	case C.EGL_BAD_CONFIG:
+		return "bad config"
+	case C.EGL_BAD_CONTEXT:
+		return "bad context"
+	case C.EGL_BAD_CURRENT_SURFACE:
+		return "bad current surface"
+	case C.EGL_BAD_DISPLAY:
+		return "bad display"
+	case C.EGL_BAD_MATCH:
+		return "bad match"
+	case C.EGL_BAD_NATIVE_PIXMAP:
+		return "bad native pixmap"
+	case C.EGL_BAD_NATIVE_WINDOW:
+		return "bad native window"
+	case C.EGL_BAD_PARAMETER:
+		return "bad parameter"
+	case C.EGL_BAD_SURFACE:
+		return "bad surface"
+	case C.EGL_CONTEXT_LOST:
+		return "context lost"
+	default:
+		return fmt.Sprintf("unknown error: %d", err)
+	}
+}
+
+var ErrGBM = errors.New("GBM error")
@@ -0,0 +1,42 @@
+// +build !linux,!windows,!darwin
+
+package glhl
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// Context represents a headless OpenGL context. No backend is implemented
+// for this platform, so it can never be successfully created.
+type Context struct{}
+
+// NewContext always fails: glhl has no backend for this platform.
+func NewContext(major, minor int, flags Flag) (Context, error) {
+	return Context{}, errUnsupportedPlatform
+}
+
+// NewSharedContext always fails: glhl has no backend for this platform.
+func NewSharedContext(parent Context, major, minor int, flags Flag) (Context, error) {
+	return Context{}, errUnsupportedPlatform
+}
+
+// Destroy cleans up the state surrounding a context
+func (Context) Destroy() {}
+
+// MakeContextCurrent activates the context, making it the new current OpenGL context.
+// gl.InitWithProcAddrFunc should be called with GetProcAddr after calling this function.
+func (Context) MakeContextCurrent() { panic(errUnsupportedPlatform) }
+
+// Release deactivates the current context, making it available for use in other threads.
+func Release() {}
+
+// GetProcAddr gets the address of an OpenGL function. For use with gl.InitWithProcAddrFunc
+func GetProcAddr(name string) unsafe.Pointer { return nil }
+
+// Error represents a context initialization error
+type Error int
+
+func (Error) Error() string { return errUnsupportedPlatform.Error() }
+
+var errUnsupportedPlatform = errors.New("glhl: unsupported platform")
@@ -0,0 +1,244 @@
+package glhl
+
+/*
+#cgo LDFLAGS: -lopengl32 -lgdi32 -luser32 -lkernel32
+#include <windows.h>
+#include <GL/gl.h>
+
+typedef HGLRC (WINAPI *PFNWGLCREATECONTEXTATTRIBSARBPROC)(HDC, HGLRC, const int *);
+typedef BOOL (WINAPI *PFNWGLCHOOSEPIXELFORMATARBPROC)(HDC, const int *, const FLOAT *, UINT, int *, UINT *);
+
+#define WGL_DRAW_TO_WINDOW_ARB 0x2001
+#define WGL_ACCELERATION_ARB 0x2003
+#define WGL_SUPPORT_OPENGL_ARB 0x2010
+#define WGL_DOUBLE_BUFFER_ARB 0x2011
+#define WGL_PIXEL_TYPE_ARB 0x2013
+#define WGL_FULL_ACCELERATION_ARB 0x2027
+#define WGL_TYPE_RGBA_ARB 0x202B
+
+#define WGL_CONTEXT_MAJOR_VERSION_ARB 0x2091
+#define WGL_CONTEXT_MINOR_VERSION_ARB 0x2092
+#define WGL_CONTEXT_FLAGS_ARB 0x2094
+#define WGL_CONTEXT_PROFILE_MASK_ARB 0x9126
+#define WGL_CONTEXT_DEBUG_BIT_ARB 0x0001
+#define WGL_CONTEXT_CORE_PROFILE_BIT_ARB 0x00000001
+#define WGL_CONTEXT_COMPATIBILITY_PROFILE_BIT_ARB 0x00000002
+
+static LRESULT CALLBACK glhlWndProc(HWND hwnd, UINT msg, WPARAM wp, LPARAM lp) {
+	return DefWindowProcW(hwnd, msg, wp, lp);
+}
+
+// glhlCreateWindow creates a hidden, message-only window to host a WGL
+// rendering context; glhl never needs to present anything to the screen.
+static HWND glhlCreateWindow(void) {
+	static const wchar_t *className = L"glhl";
+	WNDCLASSEXW wc = {0};
+	wc.cbSize = sizeof(wc);
+	wc.lpfnWndProc = glhlWndProc;
+	wc.hInstance = GetModuleHandleW(NULL);
+	wc.lpszClassName = className;
+	RegisterClassExW(&wc); // ignore "class already exists" on repeated calls
+
+	return CreateWindowExW(0, className, L"glhl", 0, 0, 0, 1, 1, HWND_MESSAGE, NULL, wc.hInstance, NULL);
+}
+
+static PIXELFORMATDESCRIPTOR glhlPFD(void) {
+	PIXELFORMATDESCRIPTOR pfd = {0};
+	pfd.nSize = sizeof(pfd);
+	pfd.nVersion = 1;
+	pfd.dwFlags = PFD_DRAW_TO_WINDOW | PFD_SUPPORT_OPENGL | PFD_DOUBLEBUFFER;
+	pfd.iPixelType = PFD_TYPE_RGBA;
+	pfd.cColorBits = 32;
+	pfd.cDepthBits = 24;
+	pfd.cStencilBits = 8;
+	return pfd;
+}
+
+// glhlProbeExtensions discovers wglChoosePixelFormatARB and
+// wglCreateContextAttribsARB. Both are only exposed once some context is
+// current, and a window's pixel format can only be set once for the life of
+// its HDC, so this stands up a throwaway window/context solely to look them
+// up, and tears it back down before returning, leaving the real window's
+// HDC untouched.
+static void glhlProbeExtensions(PFNWGLCHOOSEPIXELFORMATARBPROC *choosePixelFormat, PFNWGLCREATECONTEXTATTRIBSARBPROC *createContextAttribs) {
+	*choosePixelFormat = NULL;
+	*createContextAttribs = NULL;
+
+	HWND hwnd = glhlCreateWindow();
+	if (!hwnd) return;
+	HDC hdc = GetDC(hwnd);
+	if (!hdc) goto destroyWindow;
+
+	PIXELFORMATDESCRIPTOR pfd = glhlPFD();
+	int fmt = ChoosePixelFormat(hdc, &pfd);
+	if (!fmt || !SetPixelFormat(hdc, fmt, &pfd)) goto releaseDC;
+
+	HGLRC legacy = wglCreateContext(hdc);
+	if (!legacy) goto releaseDC;
+
+	HGLRC prevCtx = wglGetCurrentContext();
+	HDC prevDC = wglGetCurrentDC();
+	wglMakeCurrent(hdc, legacy);
+
+	*choosePixelFormat = (PFNWGLCHOOSEPIXELFORMATARBPROC)wglGetProcAddress("wglChoosePixelFormatARB");
+	*createContextAttribs = (PFNWGLCREATECONTEXTATTRIBSARBPROC)wglGetProcAddress("wglCreateContextAttribsARB");
+
+	wglMakeCurrent(prevDC, prevCtx);
+	wglDeleteContext(legacy);
+releaseDC:
+	ReleaseDC(hwnd, hdc);
+destroyWindow:
+	DestroyWindow(hwnd);
+}
+
+// glhlCreateContext sets up a pixel format on hdc (exactly once, since
+// SetPixelFormat only succeeds the first time for a given HDC) and creates
+// a context with the requested version/profile/flags.
+static HGLRC glhlCreateContext(HDC hdc, int major, int minor, int profileMask, int ctxFlags, HGLRC share) {
+	PFNWGLCHOOSEPIXELFORMATARBPROC wglChoosePixelFormatARB;
+	PFNWGLCREATECONTEXTATTRIBSARBPROC wglCreateContextAttribsARB;
+	glhlProbeExtensions(&wglChoosePixelFormatARB, &wglCreateContextAttribsARB);
+
+	PIXELFORMATDESCRIPTOR pfd = glhlPFD();
+	int fmt = 0;
+	UINT nfmt = 0;
+	if (wglChoosePixelFormatARB) {
+		int pfAttr[] = {
+			WGL_DRAW_TO_WINDOW_ARB, TRUE,
+			WGL_SUPPORT_OPENGL_ARB, TRUE,
+			WGL_DOUBLE_BUFFER_ARB, TRUE,
+			WGL_ACCELERATION_ARB, WGL_FULL_ACCELERATION_ARB,
+			WGL_PIXEL_TYPE_ARB, WGL_TYPE_RGBA_ARB,
+			0,
+		};
+		wglChoosePixelFormatARB(hdc, pfAttr, NULL, 1, &fmt, &nfmt);
+	}
+	if (!fmt || !nfmt) fmt = ChoosePixelFormat(hdc, &pfd);
+	if (!fmt || !SetPixelFormat(hdc, fmt, &pfd)) return NULL;
+
+	HGLRC ctx = NULL;
+	if (wglCreateContextAttribsARB) {
+		int ctxAttr[] = {
+			WGL_CONTEXT_MAJOR_VERSION_ARB, major,
+			WGL_CONTEXT_MINOR_VERSION_ARB, minor,
+			WGL_CONTEXT_PROFILE_MASK_ARB, profileMask,
+			WGL_CONTEXT_FLAGS_ARB, ctxFlags,
+			0,
+		};
+		ctx = wglCreateContextAttribsARB(hdc, share, ctxAttr);
+	}
+	if (!ctx) {
+		ctx = wglCreateContext(hdc);
+		if (ctx && share) wglShareLists(share, ctx);
+	}
+	return ctx;
+}
+
+static HMODULE glhlOpengl32(void) {
+	return GetModuleHandleW(L"opengl32.dll");
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// Context represents a headless OpenGL context, backed by an invisible
+// message-only window and a WGL rendering context.
+type Context struct {
+	hwnd  C.HWND
+	hdc   C.HDC
+	hglrc C.HGLRC
+}
+
+// NewContext creates a new context with the specified version and flags.
+func NewContext(major, minor int, flags Flag) (Context, error) {
+	return newContext(major, minor, flags, nil)
+}
+
+// NewSharedContext creates a new context that shares textures, buffers, and
+// other objects with parent, as if by passing share_context to
+// wglCreateContextAttribsARB.
+func NewSharedContext(parent Context, major, minor int, flags Flag) (Context, error) {
+	return newContext(major, minor, flags, parent.hglrc)
+}
+
+func newContext(major, minor int, flags Flag, share C.HGLRC) (Context, error) {
+	if flags&ES != 0 {
+		return Context{}, ErrUnsupported
+	}
+
+	hwnd := C.glhlCreateWindow()
+	if hwnd == nil {
+		return Context{}, fmt.Errorf("CreateWindowExW: %w", lastError())
+	}
+	hdc := C.GetDC(hwnd)
+	if hdc == nil {
+		C.DestroyWindow(hwnd)
+		return Context{}, ErrNoDisplay
+	}
+
+	profileMask := C.int(C.WGL_CONTEXT_CORE_PROFILE_BIT_ARB)
+	if flags&Compatibility != 0 {
+		profileMask = C.WGL_CONTEXT_COMPATIBILITY_PROFILE_BIT_ARB
+	}
+	var ctxFlags C.int
+	if flags&Debug != 0 {
+		ctxFlags |= C.WGL_CONTEXT_DEBUG_BIT_ARB
+	}
+
+	hglrc := C.glhlCreateContext(hdc, C.int(major), C.int(minor), profileMask, ctxFlags, share)
+	if hglrc == nil {
+		C.ReleaseDC(hwnd, hdc)
+		C.DestroyWindow(hwnd)
+		return Context{}, ErrNoConfig
+	}
+	return Context{hwnd: hwnd, hdc: hdc, hglrc: hglrc}, nil
+}
+
+// Destroy cleans up the state surrounding a context
+func (ctx Context) Destroy() {
+	C.wglDeleteContext(ctx.hglrc)
+	C.ReleaseDC(ctx.hwnd, ctx.hdc)
+	C.DestroyWindow(ctx.hwnd)
+}
+
+// MakeContextCurrent activates the context, making it the new current OpenGL context.
+// gl.InitWithProcAddrFunc should be called with GetProcAddr after calling this function.
+func (ctx Context) MakeContextCurrent() {
+	if C.wglMakeCurrent(ctx.hdc, ctx.hglrc) == 0 {
+		panic(lastError())
+	}
+}
+
+// Release deactivates the current context, making it available for use in other threads.
+func Release() {
+	if C.wglMakeCurrent(nil, nil) == 0 {
+		panic(lastError())
+	}
+}
+
+// GetProcAddr gets the address of an OpenGL function. For use with gl.InitWithProcAddrFunc
+func GetProcAddr(name string) unsafe.Pointer {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	if p := C.wglGetProcAddress(cname); p != nil {
+		return unsafe.Pointer(p)
+	}
+	// wglGetProcAddress only returns extension functions; pre-1.2 core
+	// functions have to be looked up directly in opengl32.dll.
+	return unsafe.Pointer(C.GetProcAddress(C.glhlOpengl32(), cname))
+}
+
+func lastError() error {
+	return Error(C.GetLastError())
+}
+
+// Error represents a context initialization error
+type Error uint32
+
+func (err Error) Error() string {
+	return fmt.Sprintf("Windows error %#x", uint32(err))
+}
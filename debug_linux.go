@@ -0,0 +1,159 @@
+package glhl
+
+/*
+#cgo LDFLAGS: -lEGL -lgbm -lGL
+#include <stdlib.h>
+#include <EGL/egl.h>
+#include <GL/gl.h>
+
+#ifndef GL_DEBUG_OUTPUT
+#define GL_DEBUG_OUTPUT 0x92E0
+#endif
+#ifndef GL_DEBUG_OUTPUT_SYNCHRONOUS
+#define GL_DEBUG_OUTPUT_SYNCHRONOUS 0x8242
+#endif
+
+typedef void (*GLDEBUGPROC)(GLenum source, GLenum type, GLuint id, GLenum severity, GLsizei length, GLchar *message, void *userParam);
+typedef void (*PFNGLDEBUGMESSAGECALLBACKPROC)(GLDEBUGPROC callback, void *userParam);
+typedef void (*PFNGLDEBUGMESSAGECONTROLPROC)(GLenum source, GLenum type, GLenum severity, GLsizei count, const GLuint *ids, GLboolean enabled);
+
+// glhlDebugCallback is exported by the //export declaration below; cgo
+// generates its prototype with non-const pointer params, so it must be
+// redeclared here (if at all) with matching, non-const types to avoid a
+// conflicting-types error.
+extern void glhlDebugCallback(GLenum source, GLenum type, GLuint id, GLenum severity, GLsizei length, GLchar *message, void *userParam);
+
+static PFNGLDEBUGMESSAGECALLBACKPROC glhlDebugMessageCallback;
+static PFNGLDEBUGMESSAGECONTROLPROC glhlDebugMessageControl;
+
+// glhlLoadDebug looks up the KHR_debug entry points, preferring the core
+// names over the KHR-suffixed ones. It returns 0 if the extension isn't
+// supported by the current context.
+static int glhlLoadDebug(void) {
+	if (!glhlDebugMessageCallback) {
+		glhlDebugMessageCallback = (PFNGLDEBUGMESSAGECALLBACKPROC)eglGetProcAddress("glDebugMessageCallback");
+		if (!glhlDebugMessageCallback)
+			glhlDebugMessageCallback = (PFNGLDEBUGMESSAGECALLBACKPROC)eglGetProcAddress("glDebugMessageCallbackKHR");
+	}
+	if (!glhlDebugMessageControl) {
+		glhlDebugMessageControl = (PFNGLDEBUGMESSAGECONTROLPROC)eglGetProcAddress("glDebugMessageControl");
+		if (!glhlDebugMessageControl)
+			glhlDebugMessageControl = (PFNGLDEBUGMESSAGECONTROLPROC)eglGetProcAddress("glDebugMessageControlKHR");
+	}
+	return glhlDebugMessageCallback != NULL && glhlDebugMessageControl != NULL;
+}
+
+static void glhlEnableDebugOutput(void) {
+	glEnable(GL_DEBUG_OUTPUT);
+	glEnable(GL_DEBUG_OUTPUT_SYNCHRONOUS); // keep the callback on the calling goroutine's stack
+	glhlDebugMessageCallback(glhlDebugCallback, NULL);
+}
+
+static void glhlControlDebug(GLenum source, GLenum type, GLenum severity, GLsizei count, const GLuint *ids, GLboolean enabled) {
+	glhlDebugMessageControl(source, type, severity, count, ids, enabled);
+}
+*/
+import "C"
+
+import (
+	"log"
+	"sync"
+	"unsafe"
+)
+
+// DebugMessageHandler receives KHR_debug messages, as installed by
+// Context.SetDebugMessageHandler.
+type DebugMessageHandler func(source, typ, id, severity uint32, message string)
+
+// debugKey identifies a Context for the purposes of routing KHR_debug
+// messages, since EGLContext handles are only meaningful alongside the
+// EGLDisplay they belong to.
+type debugKey struct {
+	dpy C.EGLDisplay
+	ctx C.EGLContext
+}
+
+var (
+	debugHandlerMu sync.Mutex
+	debugHandlers  = map[debugKey]DebugMessageHandler{}
+)
+
+// SetDebugMessageHandler installs handler to receive KHR_debug messages
+// generated while ctx is current; ctx must have been created with the Debug
+// flag. Messages are delivered synchronously, on whichever goroutine
+// happens to be calling into OpenGL at the time, so that a debugger's stack
+// trace points at the offending call. If handler is nil, messages are
+// logged via log.Default() instead.
+//
+// A context created with the Debug flag already gets a log.Default()
+// handler automatically the first time it's made current; call this to
+// install a different one.
+func (ctx Context) SetDebugMessageHandler(handler DebugMessageHandler) error {
+	if C.glhlLoadDebug() == 0 {
+		return ErrUnsupported
+	}
+	key := debugKey{ctx.dpy, ctx.ctx}
+	debugHandlerMu.Lock()
+	debugHandlers[key] = handler
+	debugHandlerMu.Unlock()
+	C.glhlEnableDebugOutput()
+	return nil
+}
+
+// enableDefaultDebugHandler lazily enables KHR_debug output for ctx,
+// routing messages to log.Default() unless a handler has already been
+// installed via SetDebugMessageHandler. Called automatically from
+// MakeContextCurrent/MakeCurrentWithSurface for contexts created with the
+// Debug flag, so Debug alone is enough to see output.
+func enableDefaultDebugHandler(ctx Context) {
+	key := debugKey{ctx.dpy, ctx.ctx}
+	debugHandlerMu.Lock()
+	_, already := debugHandlers[key]
+	if !already {
+		debugHandlers[key] = nil
+	}
+	debugHandlerMu.Unlock()
+	if already {
+		return
+	}
+	if C.glhlLoadDebug() != 0 {
+		C.glhlEnableDebugOutput()
+	}
+}
+
+// DebugControl enables or disables a class of KHR_debug messages for ctx, as
+// if by glDebugMessageControl. source, typ, and severity may each be
+// GL_DONT_CARE (0x1100) to match every value in that category; an empty ids
+// matches every message id.
+func (ctx Context) DebugControl(source, typ, severity uint32, ids []uint32, enable bool) error {
+	if C.glhlLoadDebug() == 0 {
+		return ErrUnsupported
+	}
+
+	var idsPtr *C.GLuint
+	if len(ids) > 0 {
+		idsPtr = (*C.GLuint)(unsafe.Pointer(&ids[0]))
+	}
+	var enabled C.GLboolean
+	if enable {
+		enabled = C.GL_TRUE
+	}
+	C.glhlControlDebug(C.GLenum(source), C.GLenum(typ), C.GLenum(severity), C.GLsizei(len(ids)), idsPtr, enabled)
+	return nil
+}
+
+//export glhlDebugCallback
+func glhlDebugCallback(source, typ, id, severity C.GLenum, length C.GLsizei, message *C.GLchar, userParam unsafe.Pointer) {
+	key := debugKey{C.eglGetCurrentDisplay(), C.eglGetCurrentContext()}
+	debugHandlerMu.Lock()
+	handler := debugHandlers[key]
+	debugHandlerMu.Unlock()
+	if handler == nil {
+		handler = logDebugMessage
+	}
+	handler(uint32(source), uint32(typ), uint32(id), uint32(severity), C.GoStringN(message, C.int(length)))
+}
+
+func logDebugMessage(source, typ, id, severity uint32, message string) {
+	log.Default().Printf("glhl: GL debug (source=%#x type=%#x id=%#x severity=%#x): %s", source, typ, id, severity, message)
+}
@@ -0,0 +1,79 @@
+package glhl
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Device identifies a DRM device node that can be used to create a context
+// with NewContextOnDevice.
+type Device struct {
+	// Path is the device node, e.g. /dev/dri/renderD128 or /dev/dri/card0.
+	Path string
+	// IsRenderNode is true if Path is a render node (/dev/dri/renderD*).
+	// Render nodes are preferred over card nodes, since they don't require
+	// CAP_SYS_ADMIN or an attached seat.
+	IsRenderNode bool
+	// Vendor and Product are the PCI vendor and device IDs of the GPU
+	// backing this node, read from sysfs. They are 0 if unavailable.
+	Vendor  uint32
+	Product uint32
+}
+
+// Devices enumerates the available DRM devices, with render nodes
+// (/dev/dri/renderD*) listed before card nodes (/dev/dri/card*), since
+// render nodes are usually preferable for headless rendering.
+func Devices() ([]Device, error) {
+	render, err := globDRM("renderD")
+	if err != nil {
+		return nil, err
+	}
+	card, err := globDRM("card")
+	if err != nil {
+		return nil, err
+	}
+
+	devs := make([]Device, 0, len(render)+len(card))
+	for _, path := range render {
+		devs = append(devs, newDevice(path, true))
+	}
+	for _, path := range card {
+		devs = append(devs, newDevice(path, false))
+	}
+	return devs, nil
+}
+
+func globDRM(prefix string) ([]string, error) {
+	paths, err := filepath.Glob("/dev/dri/" + prefix + "*")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func newDevice(path string, isRenderNode bool) Device {
+	name := filepath.Base(path)
+	return Device{
+		Path:         path,
+		IsRenderNode: isRenderNode,
+		Vendor:       readSysfsHexID(filepath.Join("/sys/class/drm", name, "device", "vendor")),
+		Product:      readSysfsHexID(filepath.Join("/sys/class/drm", name, "device", "device")),
+	}
+}
+
+func readSysfsHexID(path string) uint32 {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	s := strings.TrimPrefix(strings.TrimSpace(string(data)), "0x")
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return 0
+	}
+	return uint32(v)
+}
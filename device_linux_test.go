@@ -0,0 +1,43 @@
+package glhl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadSysfsHexID(t *testing.T) {
+	write := func(t *testing.T, contents string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "id")
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	cases := []struct {
+		name     string
+		contents string
+		want     uint32
+	}{
+		{"with 0x prefix", "0x1af4\n", 0x1af4},
+		{"without 0x prefix", "1af4\n", 0x1af4},
+		{"no trailing newline", "1af4", 0x1af4},
+		{"empty file", "", 0},
+		{"garbage", "not-hex\n", 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := readSysfsHexID(write(t, c.contents)); got != c.want {
+				t.Errorf("readSysfsHexID(%q) = %#x, want %#x", c.contents, got, c.want)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		if got := readSysfsHexID(filepath.Join(t.TempDir(), "missing")); got != 0 {
+			t.Errorf("readSysfsHexID(missing) = %#x, want 0", got)
+		}
+	})
+}
@@ -0,0 +1,203 @@
+package glhl
+
+/*
+#cgo LDFLAGS: -lEGL -lgbm -lGL
+#include <stdlib.h>
+#include <EGL/egl.h>
+#include <GL/gl.h>
+#include <gbm.h>
+
+#ifndef EGL_KHR_image_base
+typedef void *EGLImageKHR;
+#endif
+
+typedef EGLImageKHR (*PFNEGLCREATEIMAGEKHRPROC)(EGLDisplay, EGLContext, EGLenum, EGLClientBuffer, const EGLint *);
+typedef EGLBoolean (*PFNEGLDESTROYIMAGEKHRPROC)(EGLDisplay, EGLImageKHR);
+typedef void (*PFNGLEGLIMAGETARGETTEXTURE2DOESPROC)(GLenum target, void *image);
+
+static PFNEGLCREATEIMAGEKHRPROC glhlCreateImageKHR;
+static PFNEGLDESTROYIMAGEKHRPROC glhlDestroyImageKHR;
+static PFNGLEGLIMAGETARGETTEXTURE2DOESPROC glhlImageTargetTexture2DOES;
+
+// glhlLoadImageExt looks up the EGL_KHR_image_base entry points needed to
+// import a dma-buf as an EGLImage.
+static int glhlLoadImageExt(void) {
+	if (!glhlCreateImageKHR)
+		glhlCreateImageKHR = (PFNEGLCREATEIMAGEKHRPROC)eglGetProcAddress("eglCreateImageKHR");
+	if (!glhlDestroyImageKHR)
+		glhlDestroyImageKHR = (PFNEGLDESTROYIMAGEKHRPROC)eglGetProcAddress("eglDestroyImageKHR");
+	return glhlCreateImageKHR != NULL && glhlDestroyImageKHR != NULL;
+}
+
+// glhlLoadImageTargetTexture looks up GL_OES_EGL_image entry point needed to
+// bind an EGLImage to a texture.
+static int glhlLoadImageTargetTexture(void) {
+	if (!glhlImageTargetTexture2DOES)
+		glhlImageTargetTexture2DOES = (PFNGLEGLIMAGETARGETTEXTURE2DOESPROC)eglGetProcAddress("glEGLImageTargetTexture2DOES");
+	return glhlImageTargetTexture2DOES != NULL;
+}
+
+static EGLImageKHR glhlCreateImage(EGLDisplay dpy, EGLenum target, const EGLint *attribs) {
+	return glhlCreateImageKHR(dpy, (EGLContext)EGL_NO_CONTEXT, target, NULL, attribs);
+}
+
+static EGLBoolean glhlDestroyImage(EGLDisplay dpy, EGLImageKHR img) {
+	return glhlDestroyImageKHR(dpy, img);
+}
+
+static void glhlImageTargetTexture2D(GLenum target, EGLImageKHR image) {
+	glhlImageTargetTexture2DOES(target, (void *)image);
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// GBMBuffer is a GBM buffer object, suitable for exporting as a DMABuf via
+// Context.ExportBO.
+type GBMBuffer struct {
+	bo *C.struct_gbm_bo
+}
+
+// NewGBMBuffer allocates a new GBM buffer object on ctx's device. ctx must
+// have been created against a GBM device, i.e. via NewContextOnDevice, or via
+// NewContext when it falls back to GBM.
+func (ctx Context) NewGBMBuffer(w, h int, format uint32, usage GBMUsage) (*GBMBuffer, error) {
+	if ctx.gbm == nil {
+		return nil, ErrUnsupported
+	}
+	bo := C.gbm_bo_create(ctx.gbm, C.uint32_t(w), C.uint32_t(h), C.uint32_t(format), C.uint32_t(usage))
+	if bo == nil {
+		return nil, ErrGBM
+	}
+	return &GBMBuffer{bo: bo}, nil
+}
+
+// Destroy frees the buffer object.
+func (b *GBMBuffer) Destroy() {
+	C.gbm_bo_destroy(b.bo)
+}
+
+// DMABufPlane is one plane of a DMABuf.
+type DMABufPlane struct {
+	FD     int
+	Stride int
+	Offset int
+}
+
+// DMABuf is a GPU buffer shared across process/API boundaries as a set of
+// dma-buf file descriptors, one per plane, as produced by Context.ExportBO
+// and consumed by Context.ImportImage.
+type DMABuf struct {
+	Width, Height int
+	Format        uint32 // DRM fourcc, e.g. GBM_FORMAT_ARGB8888
+	Modifier      uint64
+	Planes        []DMABufPlane
+}
+
+// ExportBO exports bo as a DMABuf, duplicating one file descriptor per
+// plane. The caller is responsible for closing DMABuf.Planes[*].FD once
+// they're no longer needed (ImportImage does not take ownership of them).
+func (ctx Context) ExportBO(bo *GBMBuffer) (DMABuf, error) {
+	n := int(C.gbm_bo_get_plane_count(bo.bo))
+	if n <= 0 || n > 4 {
+		return DMABuf{}, ErrGBM
+	}
+
+	dma := DMABuf{
+		Width:    int(C.gbm_bo_get_width(bo.bo)),
+		Height:   int(C.gbm_bo_get_height(bo.bo)),
+		Format:   uint32(C.gbm_bo_get_format(bo.bo)),
+		Modifier: uint64(C.gbm_bo_get_modifier(bo.bo)),
+		Planes:   make([]DMABufPlane, n),
+	}
+	for i := 0; i < n; i++ {
+		fd := C.gbm_bo_get_fd_for_plane(bo.bo, C.int(i))
+		if fd < 0 {
+			for _, p := range dma.Planes[:i] {
+				syscall.Close(p.FD)
+			}
+			return DMABuf{}, ErrGBM
+		}
+		dma.Planes[i] = DMABufPlane{
+			FD:     int(fd),
+			Stride: int(C.gbm_bo_get_stride_for_plane(bo.bo, C.int(i))),
+			Offset: int(C.gbm_bo_get_offset(bo.bo, C.int(i))),
+		}
+	}
+	return dma, nil
+}
+
+const egl_LINUX_DMA_BUF_EXT C.EGLenum = 0x3270
+const egl_LINUX_DRM_FOURCC_EXT C.EGLint = 0x3271
+
+var dmaPlaneFDAttr = [4]C.EGLint{0x3272, 0x3275, 0x3278, 0x3440}
+var dmaPlaneOffsetAttr = [4]C.EGLint{0x3273, 0x3276, 0x3279, 0x3441}
+var dmaPlanePitchAttr = [4]C.EGLint{0x3274, 0x3277, 0x327A, 0x3442}
+var dmaPlaneModLoAttr = [4]C.EGLint{0x3443, 0x3445, 0x3447, 0x3449}
+var dmaPlaneModHiAttr = [4]C.EGLint{0x3444, 0x3446, 0x3448, 0x344A}
+
+// EGLImage wraps an EGLImageKHR, created by Context.ImportImage.
+type EGLImage struct {
+	dpy C.EGLDisplay
+	img C.EGLImageKHR
+}
+
+// ImportImage imports dma as an EGLImage, via EGL_EXT_image_dma_buf_import.
+// Up to 4 planes are supported.
+func (ctx Context) ImportImage(dma DMABuf) (EGLImage, error) {
+	if C.glhlLoadImageExt() == 0 {
+		return EGLImage{}, ErrUnsupported
+	}
+	if len(dma.Planes) == 0 || len(dma.Planes) > 4 {
+		return EGLImage{}, ErrUnsupported
+	}
+
+	attr := []C.EGLint{
+		C.EGL_WIDTH, C.EGLint(dma.Width),
+		C.EGL_HEIGHT, C.EGLint(dma.Height),
+		egl_LINUX_DRM_FOURCC_EXT, C.EGLint(dma.Format),
+	}
+	for i, p := range dma.Planes {
+		attr = append(attr,
+			dmaPlaneFDAttr[i], C.EGLint(p.FD),
+			dmaPlaneOffsetAttr[i], C.EGLint(p.Offset),
+			dmaPlanePitchAttr[i], C.EGLint(p.Stride),
+		)
+		if dma.Modifier != 0 {
+			attr = append(attr,
+				dmaPlaneModLoAttr[i], C.EGLint(dma.Modifier&0xffffffff),
+				dmaPlaneModHiAttr[i], C.EGLint(dma.Modifier>>32),
+			)
+		}
+	}
+	attr = append(attr, C.EGL_NONE)
+
+	img := C.glhlCreateImage(ctx.dpy, egl_LINUX_DMA_BUF_EXT, &attr[0])
+	if img == nil {
+		return EGLImage{}, fmt.Errorf("eglCreateImageKHR: %w", eglError())
+	}
+	return EGLImage{dpy: ctx.dpy, img: img}, nil
+}
+
+// Destroy frees the EGLImage. It does not close the DMABuf's file
+// descriptors, which the caller imported it from.
+func (img EGLImage) Destroy() {
+	if C.glhlDestroyImage(img.dpy, img.img) == 0 {
+		panic(Error(C.eglGetError()))
+	}
+}
+
+// BindToTexture2D binds img as the storage for the texture currently bound
+// to target (e.g. GL_TEXTURE_2D), via GL_OES_EGL_image. The relevant
+// context must be current.
+func (img EGLImage) BindToTexture2D(target uint32) error {
+	if C.glhlLoadImageTargetTexture() == 0 {
+		return ErrUnsupported
+	}
+	C.glhlImageTargetTexture2D(C.GLenum(target), img.img)
+	return nil
+}
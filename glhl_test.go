@@ -1,6 +1,9 @@
 package glhl
 
-import "testing"
+import (
+	"image"
+	"testing"
+)
 
 func TestNewContext(t *testing.T) {
 	ctx, err := NewContext(3, 3, 0)
@@ -10,3 +13,44 @@ func TestNewContext(t *testing.T) {
 	defer ctx.Destroy()
 	ctx.MakeContextCurrent()
 }
+
+func TestNewPbufferSurface(t *testing.T) {
+	ctx, err := NewContext(3, 3, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ctx.Destroy()
+
+	surf, err := ctx.NewPbufferSurface(4, 4, SurfaceOptions{RedBits: 8, GreenBits: 8, BlueBits: 8, AlphaBits: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer surf.Destroy()
+
+	ctx.MakeCurrentWithSurface(surf)
+	if _, err := surf.ReadPixels(image.Rect(0, 0, 4, 4)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewContextES(t *testing.T) {
+	ctx, err := NewContext(3, 0, ES)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ctx.Destroy()
+	ctx.MakeContextCurrent()
+}
+
+func TestSetDebugMessageHandler(t *testing.T) {
+	ctx, err := NewContext(3, 3, Debug)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ctx.Destroy()
+	ctx.MakeContextCurrent()
+
+	if err := ctx.SetDebugMessageHandler(func(source, typ, id, severity uint32, message string) {}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,206 @@
+package glhl
+
+/*
+#cgo LDFLAGS: -lEGL -lgbm -lGL
+#include <EGL/egl.h>
+#include <EGL/eglext.h>
+#include <gbm.h>
+#include <GL/gl.h>
+
+int glhlMakeContextCurrentSurface(EGLDisplay dpy, EGLContext ctx, EGLSurface surf, EGLenum api) {
+	if (!eglBindAPI(api)) goto error;
+	if (!eglMakeCurrent(dpy, surf, surf, ctx)) goto error;
+	return EGL_SUCCESS;
+error:
+	return eglGetError();
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"image"
+	"unsafe"
+)
+
+// GBMUsage is a bitmask of gbm_bo_flags describing how the buffers backing
+// a GBM surface will be used. See NewGBMSurface.
+type GBMUsage uint32
+
+const (
+	GBMUsageRendering GBMUsage = C.GBM_BO_USE_RENDERING // Buffer will be used for rendering
+	GBMUsageScanout   GBMUsage = C.GBM_BO_USE_SCANOUT   // Buffer can be used for scanout by a display controller
+	GBMUsageLinear    GBMUsage = C.GBM_BO_USE_LINEAR    // Buffer is linear, i.e. not tiled
+)
+
+// SurfaceOptions configures the EGL config chosen by NewPbufferSurface.
+// Zero-valued fields request the minimum (usually 0). NewGBMSurface takes no
+// SurfaceOptions and always uses the zero value.
+type SurfaceOptions struct {
+	RedBits, GreenBits, BlueBits, AlphaBits int
+	DepthBits, StencilBits                  int
+	SRGB                                    bool // Use an sRGB default framebuffer (EGL_GL_COLORSPACE_SRGB_KHR)
+	Samples                                 int  // Multisample count; 0 disables MSAA
+}
+
+// Surface is a renderable EGL surface, created by Context.NewPbufferSurface
+// or Context.NewGBMSurface.
+type Surface struct {
+	ctx     Context
+	surf    C.EGLSurface
+	gbmSurf *C.struct_gbm_surface
+	w, h    int
+}
+
+// NewPbufferSurface creates an off-screen pbuffer surface of the given size.
+func (ctx Context) NewPbufferSurface(w, h int, opts SurfaceOptions) (*Surface, error) {
+	conf, err := chooseSurfaceConfig(ctx.dpy, opts, C.EGL_PBUFFER_BIT)
+	if err != nil {
+		return nil, err
+	}
+
+	attr := []C.EGLint{
+		C.EGL_WIDTH, C.EGLint(w),
+		C.EGL_HEIGHT, C.EGLint(h),
+	}
+	if opts.SRGB {
+		attr = append(attr, C.EGL_GL_COLORSPACE, C.EGL_GL_COLORSPACE_SRGB_KHR)
+	}
+	attr = append(attr, C.EGL_NONE)
+
+	surf := C.eglCreatePbufferSurface(ctx.dpy, conf, &attr[0])
+	if surf == C.EGLSurface(C.EGL_NO_SURFACE) {
+		return nil, fmt.Errorf("eglCreatePbufferSurface: %w", eglError())
+	}
+	return &Surface{ctx: ctx, surf: surf, w: w, h: h}, nil
+}
+
+// NewGBMSurface creates a surface backed by a GBM window. The surface's
+// buffers are not exposed as GBMBuffer values, so they cannot be passed to
+// ExportBO; use NewGBMBuffer for buffers to export. ctx must have been
+// created against a GBM device, i.e. via NewContextOnDevice, or via
+// NewContext when it falls back to GBM.
+func (ctx Context) NewGBMSurface(w, h int, format uint32, usage GBMUsage) (*Surface, error) {
+	if ctx.gbm == nil {
+		return nil, ErrUnsupported
+	}
+
+	gbmSurf := C.gbm_surface_create(ctx.gbm, C.uint32_t(w), C.uint32_t(h), C.uint32_t(format), C.uint32_t(usage))
+	if gbmSurf == nil {
+		return nil, ErrGBM
+	}
+
+	conf, err := chooseSurfaceConfig(ctx.dpy, SurfaceOptions{}, C.EGL_WINDOW_BIT)
+	if err != nil {
+		C.gbm_surface_destroy(gbmSurf)
+		return nil, err
+	}
+
+	surf := C.eglCreatePlatformWindowSurface(ctx.dpy, conf, unsafe.Pointer(gbmSurf), nil)
+	if surf == C.EGLSurface(C.EGL_NO_SURFACE) {
+		C.gbm_surface_destroy(gbmSurf)
+		return nil, fmt.Errorf("eglCreatePlatformWindowSurface: %w", eglError())
+	}
+
+	return &Surface{ctx: ctx, surf: surf, gbmSurf: gbmSurf, w: w, h: h}, nil
+}
+
+func chooseSurfaceConfig(dpy C.EGLDisplay, opts SurfaceOptions, surfaceType C.EGLint) (C.EGLConfig, error) {
+	attr := []C.EGLint{
+		C.EGL_CONFIG_CAVEAT, C.EGL_NONE,
+		C.EGL_CONFORMANT, C.EGL_OPENGL_BIT,
+		C.EGL_RENDERABLE_TYPE, C.EGL_OPENGL_BIT,
+		C.EGL_SURFACE_TYPE, surfaceType,
+		C.EGL_RED_SIZE, C.EGLint(opts.RedBits),
+		C.EGL_GREEN_SIZE, C.EGLint(opts.GreenBits),
+		C.EGL_BLUE_SIZE, C.EGLint(opts.BlueBits),
+		C.EGL_ALPHA_SIZE, C.EGLint(opts.AlphaBits),
+		C.EGL_DEPTH_SIZE, C.EGLint(opts.DepthBits),
+		C.EGL_STENCIL_SIZE, C.EGLint(opts.StencilBits),
+	}
+	if opts.Samples > 0 {
+		attr = append(attr, C.EGL_SAMPLE_BUFFERS, 1, C.EGL_SAMPLES, C.EGLint(opts.Samples))
+	}
+	attr = append(attr, C.EGL_NONE)
+
+	var nconf C.EGLint
+	var conf C.EGLConfig
+	if C.eglChooseConfig(dpy, &attr[0], &conf, 1, &nconf) == 0 {
+		return conf, fmt.Errorf("eglChooseConfig: %w", eglError())
+	}
+	if nconf < 1 {
+		return conf, ErrNoConfig
+	}
+	return conf, nil
+}
+
+// MakeCurrentWithSurface activates the context with surf bound as both the
+// draw and read surface, making it the new current OpenGL context.
+// gl.InitWithProcAddrFunc should be called with GetProcAddr after calling
+// this function.
+func (ctx Context) MakeCurrentWithSurface(surf *Surface) {
+	code := C.glhlMakeContextCurrentSurface(ctx.dpy, ctx.ctx, surf.surf, ctx.api)
+	if code != C.EGL_SUCCESS {
+		panic(Error(code))
+	}
+	if ctx.debug {
+		enableDefaultDebugHandler(ctx)
+	}
+}
+
+// ReadPixels reads back rect from the surface's current draw buffer as
+// 8-bit RGBA. The surface's context must be current.
+func (s *Surface) ReadPixels(rect image.Rectangle) (*image.NRGBA, error) {
+	C.glFinish()
+
+	img := image.NewNRGBA(rect)
+	if len(img.Pix) == 0 {
+		return img, nil
+	}
+	C.glReadPixels(
+		C.GLint(rect.Min.X), C.GLint(rect.Min.Y),
+		C.GLsizei(rect.Dx()), C.GLsizei(rect.Dy()),
+		C.GL_RGBA, C.GL_UNSIGNED_BYTE,
+		unsafe.Pointer(&img.Pix[0]),
+	)
+	if code := C.glGetError(); code != C.GL_NO_ERROR {
+		return nil, fmt.Errorf("glReadPixels: GL error %#x", uint32(code))
+	}
+
+	// glReadPixels fills rows bottom-to-top (OpenGL's window-coordinate
+	// origin is the bottom-left corner), while image.NRGBA's row 0 is
+	// conventionally the top of the image.
+	flipRows(img)
+	return img, nil
+}
+
+func flipRows(img *image.NRGBA) {
+	stride := img.Stride
+	tmp := make([]uint8, stride)
+	for top, bottom := 0, img.Rect.Dy()-1; top < bottom; top, bottom = top+1, bottom-1 {
+		a := img.Pix[top*stride : top*stride+stride]
+		b := img.Pix[bottom*stride : bottom*stride+stride]
+		copy(tmp, a)
+		copy(a, b)
+		copy(b, tmp)
+	}
+}
+
+// SwapBuffers presents the surface's back buffer. Only meaningful for
+// surfaces created with NewGBMSurface.
+func (s *Surface) SwapBuffers() error {
+	if C.eglSwapBuffers(s.ctx.dpy, s.surf) == 0 {
+		return fmt.Errorf("eglSwapBuffers: %w", eglError())
+	}
+	return nil
+}
+
+// Destroy cleans up the resources associated with the surface.
+func (s *Surface) Destroy() {
+	if C.eglDestroySurface(s.ctx.dpy, s.surf) == 0 {
+		panic(Error(C.eglGetError()))
+	}
+	if s.gbmSurf != nil {
+		C.gbm_surface_destroy(s.gbmSurf)
+	}
+}